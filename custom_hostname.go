@@ -1,9 +1,14 @@
 package cloudflare
 
 import (
+	"context"
 	"encoding/json"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -37,14 +42,34 @@ type CustomHostnameOwnershipVerification struct {
 	Value string `json:"value,omitempty"`
 }
 
+// SSLValidationRecord represents a single set of domain control validation
+// tokens for a custom hostname's SSL certificate. Depending on Method, a
+// hostname may be validated via the Cname* fields, the Txt* fields, the
+// Http* fields, or by sending an approval email to one of Emails.
+type SSLValidationRecord struct {
+	CnameTarget string   `json:"cname_target,omitempty"`
+	CnameName   string   `json:"cname,omitempty"`
+	TxtName     string   `json:"txt_name,omitempty"`
+	TxtValue    string   `json:"txt_value,omitempty"`
+	HTTPUrl     string   `json:"http_url,omitempty"`
+	HTTPBody    string   `json:"http_body,omitempty"`
+	Emails      []string `json:"emails,omitempty"`
+}
+
 // CustomHostnameSSL represents the SSL section in a given custom hostname.
 type CustomHostnameSSL struct {
-	Status      string                    `json:"status,omitempty"`
-	Method      string                    `json:"method,omitempty"`
-	Type        string                    `json:"type,omitempty"`
-	CnameTarget string                    `json:"cname_target,omitempty"`
-	CnameName   string                    `json:"cname,omitempty"`
-	Settings    CustomHostnameSSLSettings `json:"settings,omitempty"`
+	Status            string                    `json:"status,omitempty"`
+	Method            string                    `json:"method,omitempty"`
+	Type              string                    `json:"type,omitempty"`
+	CnameTarget       string                    `json:"cname_target,omitempty"`
+	CnameName         string                    `json:"cname,omitempty"`
+	Settings          CustomHostnameSSLSettings `json:"settings,omitempty"`
+	ValidationRecords []SSLValidationRecord     `json:"validation_records,omitempty"`
+	// CustomCertBundle and CustomKey carry a customer-supplied (BYO)
+	// certificate and private key. They are only meaningful when Method is
+	// "custom" and are write-only: the API never echoes CustomKey back.
+	CustomCertBundle string `json:"custom_certificate,omitempty"`
+	CustomKey        string `json:"custom_key,omitempty"`
 }
 
 // CustomMetadata defines custom metadata for the hostname. This requires logic to be implemented by Cloudflare to act on the data provided.
@@ -88,13 +113,50 @@ type CustomHostnameFallbackOriginResponse struct {
 	Response
 }
 
+// UpdateCustomHostname modifies the origin, SSL configuration and metadata
+// for the given custom hostname in the given zone.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
+func (api *API) UpdateCustomHostname(zoneID string, customHostnameID string, ch CustomHostname) (*CustomHostnameResponse, error) {
+	return api.UpdateCustomHostnameContext(context.Background(), zoneID, customHostnameID, ch)
+}
+
+// UpdateCustomHostnameContext is like UpdateCustomHostname, with ctx
+// threaded into the underlying request so it can be cancelled or bounded by
+// a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
+func (api *API) UpdateCustomHostnameContext(ctx context.Context, zoneID string, customHostnameID string, ch CustomHostname) (*CustomHostnameResponse, error) {
+	uri := "/zones/" + zoneID + "/custom_hostnames/" + customHostnameID
+	res, err := api.makeRequestContext(ctx, "PATCH", uri, ch)
+	if err != nil {
+		return nil, errors.Wrap(err, errMakeRequestError)
+	}
+
+	var response *CustomHostnameResponse
+	err = json.Unmarshal(res, &response)
+	if err != nil {
+		return nil, errors.Wrap(err, errUnmarshalError)
+	}
+	return response, nil
+}
+
 // UpdateCustomHostnameSSL modifies SSL configuration for the given custom
 // hostname in the given zone.
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
 func (api *API) UpdateCustomHostnameSSL(zoneID string, customHostnameID string, ssl CustomHostnameSSL) (*CustomHostnameResponse, error) {
+	return api.UpdateCustomHostnameSSLContext(context.Background(), zoneID, customHostnameID, ssl)
+}
+
+// UpdateCustomHostnameSSLContext is like UpdateCustomHostnameSSL, with ctx
+// threaded into the underlying request so it can be cancelled or bounded by
+// a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
+func (api *API) UpdateCustomHostnameSSLContext(ctx context.Context, zoneID string, customHostnameID string, ssl CustomHostnameSSL) (*CustomHostnameResponse, error) {
 	uri := "/zones/" + zoneID + "/custom_hostnames/" + customHostnameID
-	res, err := api.makeRequest("PATCH", uri, ssl)
+	res, err := api.makeRequestContext(ctx, "PATCH", uri, ssl)
 	if err != nil {
 		return nil, errors.Wrap(err, errMakeRequestError)
 	}
@@ -107,13 +169,56 @@ func (api *API) UpdateCustomHostnameSSL(zoneID string, customHostnameID string,
 	return response, nil
 }
 
+// UploadCustomHostnameCertificate uploads a customer-supplied (BYO)
+// certificate and private key for the given custom hostname, switching its
+// SSL Method to "custom".
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
+func (api *API) UploadCustomHostnameCertificate(zoneID, customHostnameID, cert, key string) (*CustomHostnameResponse, error) {
+	return api.UpdateCustomHostnameSSL(zoneID, customHostnameID, CustomHostnameSSL{
+		Method:           "custom",
+		CustomCertBundle: cert,
+		CustomKey:        key,
+	})
+}
+
+// ValidateCustomHostname triggers a new domain control validation attempt
+// for the given custom hostname, re-checking its ValidationRecords without
+// requiring the hostname to be re-created.
+//
+// This re-submits the hostname's current Method, Type and Settings alongside
+// the PATCH; UpdateCustomHostnameSSL replaces the whole ssl object, so a
+// narrower payload would otherwise clobber those fields server-side.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-update-custom-hostname-configuration
+func (api *API) ValidateCustomHostname(zoneID, customHostnameID string) (*CustomHostnameResponse, error) {
+	ch, err := api.CustomHostname(zoneID, customHostnameID)
+	if err != nil {
+		return nil, errors.Wrap(err, "CustomHostname command failed")
+	}
+	return api.UpdateCustomHostnameSSL(zoneID, customHostnameID, CustomHostnameSSL{
+		Method:   ch.SSL.Method,
+		Type:     ch.SSL.Type,
+		Settings: ch.SSL.Settings,
+	})
+}
+
 // DeleteCustomHostname deletes a custom hostname (and any issued SSL
 // certificates).
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-delete-a-custom-hostname-and-any-issued-ssl-certificates-
 func (api *API) DeleteCustomHostname(zoneID string, customHostnameID string) error {
+	return api.DeleteCustomHostnameContext(context.Background(), zoneID, customHostnameID)
+}
+
+// DeleteCustomHostnameContext is like DeleteCustomHostname, with ctx
+// threaded into the underlying request so it can be cancelled or bounded by
+// a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-delete-a-custom-hostname-and-any-issued-ssl-certificates-
+func (api *API) DeleteCustomHostnameContext(ctx context.Context, zoneID string, customHostnameID string) error {
 	uri := "/zones/" + zoneID + "/custom_hostnames/" + customHostnameID
-	res, err := api.makeRequest("DELETE", uri, nil)
+	res, err := api.makeRequestContext(ctx, "DELETE", uri, nil)
 	if err != nil {
 		return errors.Wrap(err, errMakeRequestError)
 	}
@@ -131,8 +236,17 @@ func (api *API) DeleteCustomHostname(zoneID string, customHostnameID string) err
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-create-custom-hostname
 func (api *API) CreateCustomHostname(zoneID string, ch CustomHostname) (*CustomHostnameResponse, error) {
+	return api.CreateCustomHostnameContext(context.Background(), zoneID, ch)
+}
+
+// CreateCustomHostnameContext is like CreateCustomHostname, with ctx
+// threaded into the underlying request so it can be cancelled or bounded by
+// a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-create-custom-hostname
+func (api *API) CreateCustomHostnameContext(ctx context.Context, zoneID string, ch CustomHostname) (*CustomHostnameResponse, error) {
 	uri := "/zones/" + zoneID + "/custom_hostnames"
-	res, err := api.makeRequest("POST", uri, ch)
+	res, err := api.makeRequestContext(ctx, "POST", uri, ch)
 	if err != nil {
 		return nil, errors.Wrap(err, errMakeRequestError)
 	}
@@ -153,6 +267,14 @@ func (api *API) CreateCustomHostname(zoneID string, ch CustomHostname) (*CustomH
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-list-custom-hostnames
 func (api *API) CustomHostnames(zoneID string, page int, filter CustomHostname) ([]CustomHostname, ResultInfo, error) {
+	return api.CustomHostnamesContext(context.Background(), zoneID, page, filter)
+}
+
+// CustomHostnamesContext is like CustomHostnames, with ctx threaded into the
+// underlying request so it can be cancelled or bounded by a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-list-custom-hostnames
+func (api *API) CustomHostnamesContext(ctx context.Context, zoneID string, page int, filter CustomHostname) ([]CustomHostname, ResultInfo, error) {
 	v := url.Values{}
 	v.Set("per_page", "50")
 	v.Set("page", strconv.Itoa(page))
@@ -162,7 +284,7 @@ func (api *API) CustomHostnames(zoneID string, page int, filter CustomHostname)
 	query := "?" + v.Encode()
 
 	uri := "/zones/" + zoneID + "/custom_hostnames" + query
-	res, err := api.makeRequest("GET", uri, nil)
+	res, err := api.makeRequestContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return []CustomHostname{}, ResultInfo{}, errors.Wrap(err, errMakeRequestError)
 	}
@@ -179,8 +301,16 @@ func (api *API) CustomHostnames(zoneID string, page int, filter CustomHostname)
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-custom-hostname-configuration-details
 func (api *API) CustomHostname(zoneID string, customHostnameID string) (CustomHostname, error) {
+	return api.CustomHostnameContext(context.Background(), zoneID, customHostnameID)
+}
+
+// CustomHostnameContext is like CustomHostname, with ctx threaded into the
+// underlying request so it can be cancelled or bounded by a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-custom-hostname-configuration-details
+func (api *API) CustomHostnameContext(ctx context.Context, zoneID string, customHostnameID string) (CustomHostname, error) {
 	uri := "/zones/" + zoneID + "/custom_hostnames/" + customHostnameID
-	res, err := api.makeRequest("GET", uri, nil)
+	res, err := api.makeRequestContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return CustomHostname{}, errors.Wrap(err, errMakeRequestError)
 	}
@@ -212,8 +342,17 @@ func (api *API) CustomHostnameIDByName(zoneID string, hostname string) (string,
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-fallback-origin-for-a-zone-update-fallback-origin-for-custom-hostnames
 func (api *API) UpdateCustomHostnameFallbackOrigin(zoneID string, chfo CustomHostnameFallbackOrigin) (*CustomHostnameFallbackOriginResponse, error) {
+	return api.UpdateCustomHostnameFallbackOriginContext(context.Background(), zoneID, chfo)
+}
+
+// UpdateCustomHostnameFallbackOriginContext is like
+// UpdateCustomHostnameFallbackOrigin, with ctx threaded into the underlying
+// request so it can be cancelled or bounded by a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-fallback-origin-for-a-zone-update-fallback-origin-for-custom-hostnames
+func (api *API) UpdateCustomHostnameFallbackOriginContext(ctx context.Context, zoneID string, chfo CustomHostnameFallbackOrigin) (*CustomHostnameFallbackOriginResponse, error) {
 	uri := "/zones/" + zoneID + "/custom_hostnames/fallback_origin"
-	res, err := api.makeRequest("PUT", uri, chfo)
+	res, err := api.makeRequestContext(ctx, "PUT", uri, chfo)
 	if err != nil {
 		return nil, errors.Wrap(err, errMakeRequestError)
 	}
@@ -230,8 +369,17 @@ func (api *API) UpdateCustomHostnameFallbackOrigin(zoneID string, chfo CustomHos
 //
 // API reference: https://api.cloudflare.com/#custom-hostname-fallback-origin-for-a-zone-properties
 func (api *API) CustomHostnameFallbackOrigin(zoneID string) (CustomHostnameFallbackOrigin, error) {
+	return api.CustomHostnameFallbackOriginContext(context.Background(), zoneID)
+}
+
+// CustomHostnameFallbackOriginContext is like CustomHostnameFallbackOrigin,
+// with ctx threaded into the underlying request so it can be cancelled or
+// bounded by a deadline.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-fallback-origin-for-a-zone-properties
+func (api *API) CustomHostnameFallbackOriginContext(ctx context.Context, zoneID string) (CustomHostnameFallbackOrigin, error) {
 	uri := "/zones/" + zoneID + "/custom_hostnames/fallback_origin"
-	res, err := api.makeRequest("GET", uri, nil)
+	res, err := api.makeRequestContext(ctx, "GET", uri, nil)
 	if err != nil {
 		return CustomHostnameFallbackOrigin{}, errors.Wrap(err, errMakeRequestError)
 	}
@@ -244,3 +392,531 @@ func (api *API) CustomHostnameFallbackOrigin(zoneID string) (CustomHostnameFallb
 
 	return response.Result, nil
 }
+
+// ListCustomHostnamesParams represents the parameters used to list custom
+// hostnames within a zone. PerPage and Page are filled in with sane
+// defaults by ListCustomHostnames when left zero.
+type ListCustomHostnamesParams struct {
+	Page      int
+	PerPage   int
+	Hostname  string
+	SSLStatus string
+	Direction string
+	Order     string
+}
+
+// CustomHostnameIterator lazily walks the custom hostnames for a zone that
+// match the given ListCustomHostnamesParams, fetching subsequent pages from
+// the API as needed. Use it as:
+//
+//	it := api.ListCustomHostnames(ctx, zoneID, cloudflare.ListCustomHostnamesParams{})
+//	for it.Next() {
+//		ch := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle error
+//	}
+type CustomHostnameIterator struct {
+	ctx    context.Context
+	api    *API
+	zoneID string
+	params ListCustomHostnamesParams
+
+	page []CustomHostname
+	pos  int
+	cur  CustomHostname
+
+	exhausted bool
+	err       error
+}
+
+// ListCustomHostnames returns an iterator over the custom hostnames for
+// zoneID that match params. Pagination against the API happens
+// transparently as the iterator is advanced with Next.
+//
+// API reference: https://api.cloudflare.com/#custom-hostname-for-a-zone-list-custom-hostnames
+func (api *API) ListCustomHostnames(ctx context.Context, zoneID string, params ListCustomHostnamesParams) *CustomHostnameIterator {
+	if params.Page <= 0 {
+		params.Page = 1
+	}
+	if params.PerPage <= 0 {
+		params.PerPage = 50
+	}
+	return &CustomHostnameIterator{ctx: ctx, api: api, zoneID: zoneID, params: params}
+}
+
+// Next advances the iterator and reports whether a value is available via
+// Value. It returns false once iteration has completed or an error has
+// occurred, in which case the caller should inspect Err.
+func (it *CustomHostnameIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos >= len(it.page) {
+		if it.exhausted {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+	it.cur = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// Value returns the custom hostname most recently produced by Next.
+func (it *CustomHostnameIterator) Value() CustomHostname {
+	return it.cur
+}
+
+// Err returns the first error encountered while iterating, if any.
+func (it *CustomHostnameIterator) Err() error {
+	return it.err
+}
+
+func (it *CustomHostnameIterator) fetch() error {
+	page, info, err := it.api.listCustomHostnames(it.ctx, it.zoneID, it.params)
+	if err != nil {
+		return err
+	}
+	it.page = page
+	it.pos = 0
+	it.params.Page++
+	if info.Page >= info.TotalPages || len(page) == 0 {
+		it.exhausted = true
+	}
+	return nil
+}
+
+// listCustomHostnames fetches a single page of custom hostnames using the
+// full set of ListCustomHostnamesParams, unlike the legacy CustomHostnames
+// which only supports filtering by hostname and a fixed page size.
+func (api *API) listCustomHostnames(ctx context.Context, zoneID string, params ListCustomHostnamesParams) ([]CustomHostname, ResultInfo, error) {
+	v := url.Values{}
+	v.Set("per_page", strconv.Itoa(params.PerPage))
+	v.Set("page", strconv.Itoa(params.Page))
+	if params.Hostname != "" {
+		v.Set("hostname", params.Hostname)
+	}
+	if params.SSLStatus != "" {
+		v.Set("ssl", params.SSLStatus)
+	}
+	if params.Direction != "" {
+		v.Set("direction", params.Direction)
+	}
+	if params.Order != "" {
+		v.Set("order", params.Order)
+	}
+	query := "?" + v.Encode()
+
+	uri := "/zones/" + zoneID + "/custom_hostnames" + query
+	res, err := api.makeRequestContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return []CustomHostname{}, ResultInfo{}, errors.Wrap(err, errMakeRequestError)
+	}
+	var customHostnameListResponse CustomHostnameListResponse
+	err = json.Unmarshal(res, &customHostnameListResponse)
+	if err != nil {
+		return []CustomHostname{}, ResultInfo{}, errors.Wrap(err, errUnmarshalError)
+	}
+
+	return customHostnameListResponse.Result, customHostnameListResponse.ResultInfo, nil
+}
+
+// ListAllCustomHostnames fetches every custom hostname for zoneID that
+// matches params, transparently paginating until the result set is
+// exhausted. Prefer ListCustomHostnames when the zone may have a very large
+// number of custom hostnames, since ListAllCustomHostnames buffers the
+// entire result in memory.
+func (api *API) ListAllCustomHostnames(ctx context.Context, zoneID string, params ListCustomHostnamesParams) ([]CustomHostname, error) {
+	var out []CustomHostname
+	it := api.ListCustomHostnames(ctx, zoneID, params)
+	for it.Next() {
+		out = append(out, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// defaultWaitForCustomHostnameSSLInterval is the initial delay between polls
+// in WaitForCustomHostnameSSL. It doubles after every poll, up to
+// maxWaitForCustomHostnameSSLInterval, until the certificate reaches a
+// terminal state or the wait times out.
+const (
+	defaultWaitForCustomHostnameSSLInterval = 5 * time.Second
+	maxWaitForCustomHostnameSSLInterval     = time.Minute
+)
+
+var (
+	// ErrSSLValidationTimeout is returned by WaitForCustomHostnameSSL when
+	// WaitOptions.Timeout elapses before the custom hostname's SSL
+	// certificate becomes active.
+	ErrSSLValidationTimeout = errors.New("timed out waiting for custom hostname SSL certificate to validate")
+
+	// ErrSSLValidationFailed is wrapped around the custom hostname's
+	// VerificationErrors and returned by WaitForCustomHostnameSSL when the
+	// hostname reaches a state it cannot validate from.
+	ErrSSLValidationFailed = errors.New("custom hostname SSL validation failed")
+)
+
+// WaitOptions configures the polling behaviour of WaitForCustomHostnameSSL.
+type WaitOptions struct {
+	// Interval is the initial delay between polls. It defaults to
+	// defaultWaitForCustomHostnameSSLInterval and backs off exponentially,
+	// up to maxWaitForCustomHostnameSSLInterval, on every subsequent poll.
+	Interval time.Duration
+	// Timeout bounds the total time spent polling. Zero means no timeout
+	// beyond whatever deadline ctx itself carries.
+	Timeout time.Duration
+	// OnStatusChange, when set, is called every time the custom hostname's
+	// SSL.Status transitions, e.g. "pending_validation" -> "pending_issuance".
+	OnStatusChange func(old, new string)
+}
+
+// WaitForCustomHostnameSSL polls the given custom hostname until its SSL
+// certificate becomes active, reporting "pending_validation",
+// "pending_issuance", "pending_deployment", "initializing" and "active"
+// transitions through opts.OnStatusChange as they happen. It gives up with
+// ErrSSLValidationTimeout if opts.Timeout elapses, and with
+// ErrSSLValidationFailed (wrapping the hostname's VerificationErrors) if the
+// hostname moves into a state it cannot validate from. This saves callers
+// from hand-rolling the common create-then-poll-until-active workflow.
+func (api *API) WaitForCustomHostnameSSL(ctx context.Context, zoneID, customHostnameID string, opts WaitOptions) (CustomHostname, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultWaitForCustomHostnameSSLInterval
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var lastStatus string
+	for {
+		ch, err := api.CustomHostnameContext(ctx, zoneID, customHostnameID)
+		if err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return CustomHostname{}, ErrSSLValidationTimeout
+			}
+			return CustomHostname{}, err
+		}
+
+		status := ch.SSL.Status
+		if status != lastStatus {
+			if lastStatus != "" && opts.OnStatusChange != nil {
+				opts.OnStatusChange(lastStatus, status)
+			}
+			lastStatus = status
+		}
+
+		if status == "active" {
+			return ch, nil
+		}
+		// VerificationErrors is populated transiently during
+		// pending_validation while DNS/CAA propagates or delegated DCV
+		// hasn't been observed yet, so its mere presence doesn't mean the
+		// hostname can never validate. Only give up once the hostname has
+		// actually reached a terminal failure state.
+		if ch.Status == REMOVED || status == "validation_timed_out" {
+			return ch, errors.Wrap(ErrSSLValidationFailed, strings.Join(ch.VerificationErrors, "; "))
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return ch, ErrSSLValidationTimeout
+			}
+			return ch, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		interval *= 2
+		if interval > maxWaitForCustomHostnameSSLInterval {
+			interval = maxWaitForCustomHostnameSSLInterval
+		}
+	}
+}
+
+// ReconcileAction describes what ReconcileCustomHostnames did, or would do
+// under ReconcileOptions.DryRun, for a single hostname.
+type ReconcileAction string
+
+const (
+	// ReconcileActionCreate means the hostname existed in desired but not
+	// in the zone's current custom hostnames.
+	ReconcileActionCreate ReconcileAction = "create"
+	// ReconcileActionUpdate means the hostname existed in both desired and
+	// current but its origin, SSL method/type, settings, or metadata
+	// differed.
+	ReconcileActionUpdate ReconcileAction = "update"
+	// ReconcileActionDelete means the hostname existed in the zone's
+	// current custom hostnames but not in desired.
+	ReconcileActionDelete ReconcileAction = "delete"
+	// ReconcileActionNoop means the hostname already matched desired.
+	ReconcileActionNoop ReconcileAction = "noop"
+)
+
+// ReconcileOptions configures ReconcileCustomHostnames.
+type ReconcileOptions struct {
+	// Concurrency bounds how many Create/Update/Delete calls are in flight
+	// at once. Defaults to 4.
+	Concurrency int
+	// MaxRetries bounds how many times a single hostname's call is retried
+	// after a 429 or 5xx response, with exponential backoff between
+	// attempts. Defaults to 3.
+	MaxRetries int
+	// DryRun, when true, computes and returns the diff without issuing any
+	// Create, Update or Delete calls.
+	DryRun bool
+}
+
+// ReconcileResult is the outcome of reconciling a single hostname.
+type ReconcileResult struct {
+	Hostname string
+	Action   ReconcileAction
+	Error    error
+}
+
+// ReconcileReport summarizes the outcome of a ReconcileCustomHostnames call,
+// with one ReconcileResult per hostname considered.
+type ReconcileReport struct {
+	Results []ReconcileResult
+}
+
+// reconcileCustomHostnamesTask is the work item type used internally by
+// ReconcileCustomHostnames; run is nil for ReconcileActionNoop.
+type reconcileCustomHostnamesTask struct {
+	hostname string
+	action   ReconcileAction
+	run      func() error
+}
+
+// customHostnamesEqual reports whether current already matches desired
+// closely enough that no update is needed.
+func customHostnamesEqual(current, desired CustomHostname) bool {
+	return current.CustomOriginServer == desired.CustomOriginServer &&
+		current.SSL.Method == desired.SSL.Method &&
+		current.SSL.Type == desired.SSL.Type &&
+		reflect.DeepEqual(current.SSL.Settings, desired.SSL.Settings) &&
+		reflect.DeepEqual(current.CustomMetadata, desired.CustomMetadata)
+}
+
+// isRetryableReconcileError reports whether err looks like a transient
+// failure (HTTP 429 or 5xx) worth retrying. makeRequest does not currently
+// preserve the HTTP status code on its error, so this is a best-effort
+// string match rather than an exact check.
+func isRetryableReconcileError(err error) bool {
+	msg := err.Error()
+	for _, code := range []string{"429", "500", "502", "503", "504"} {
+		if strings.Contains(msg, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileBackoff returns the delay before retry attempt n (0-indexed),
+// doubling from 500ms up to a 30s ceiling.
+func reconcileBackoff(attempt int) time.Duration {
+	d := 500 * time.Millisecond * time.Duration(uint(1)<<uint(attempt))
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// cloudflareAPIRateLimiter is a token-bucket limiter tuned to Cloudflare's
+// default 1200 requests per 5 minutes per-user API limit. The bucket starts
+// empty and refills one token every window/limit, so sustained throughput
+// is capped at limit/window from the very first call rather than only after
+// an initial burst of up to limit requests has drained.
+type cloudflareAPIRateLimiter struct {
+	tokens chan struct{}
+	stop   chan struct{}
+}
+
+func newCloudflareAPIRateLimiter() *cloudflareAPIRateLimiter {
+	return newCloudflareAPIRateLimiterWithLimit(1200, 5*time.Minute)
+}
+
+// newCloudflareAPIRateLimiterWithLimit builds a cloudflareAPIRateLimiter
+// refilling at limit tokens per window; split out from
+// newCloudflareAPIRateLimiter so tests can use a much shorter window than
+// Cloudflare's real one.
+func newCloudflareAPIRateLimiterWithLimit(limit int, window time.Duration) *cloudflareAPIRateLimiter {
+	rl := &cloudflareAPIRateLimiter{
+		tokens: make(chan struct{}, limit),
+		stop:   make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(window / time.Duration(limit))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				select {
+				case rl.tokens <- struct{}{}:
+				default:
+				}
+			case <-rl.stop:
+				return
+			}
+		}
+	}()
+	return rl
+}
+
+func (rl *cloudflareAPIRateLimiter) wait(ctx context.Context) error {
+	select {
+	case <-rl.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (rl *cloudflareAPIRateLimiter) close() {
+	close(rl.stop)
+}
+
+// ReconcileCustomHostnames diffs desired against the zone's current custom
+// hostnames and issues the Create/Update/Delete calls needed to bring the
+// zone in line, using a bounded worker pool (opts.Concurrency) and a
+// token-bucket rate limiter tuned to Cloudflare's 1200 requests / 5 minutes
+// API limit. Calls that fail with what looks like a 429 or 5xx are retried
+// with exponential backoff up to opts.MaxRetries times. Pass opts.DryRun to
+// compute the diff without mutating anything. This is meant for bulk
+// imports from an external inventory (a DNS tree, Terraform state, etc.)
+// where hand-rolling the diff and pagination for hundreds of hostnames is
+// impractical.
+func (api *API) ReconcileCustomHostnames(ctx context.Context, zoneID string, desired []CustomHostname, opts ReconcileOptions) (ReconcileReport, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+
+	current, err := api.ListAllCustomHostnames(ctx, zoneID, ListCustomHostnamesParams{})
+	if err != nil {
+		return ReconcileReport{}, errors.Wrap(err, "ListAllCustomHostnames command failed")
+	}
+
+	currentByHostname := make(map[string]CustomHostname, len(current))
+	for _, ch := range current {
+		currentByHostname[ch.Hostname] = ch
+	}
+	desiredHostnames := make(map[string]struct{}, len(desired))
+
+	var tasks []reconcileCustomHostnamesTask
+	for _, want := range desired {
+		want := want
+		desiredHostnames[want.Hostname] = struct{}{}
+
+		have, exists := currentByHostname[want.Hostname]
+		switch {
+		case !exists:
+			tasks = append(tasks, reconcileCustomHostnamesTask{
+				hostname: want.Hostname,
+				action:   ReconcileActionCreate,
+				run: func() error {
+					_, err := api.CreateCustomHostnameContext(ctx, zoneID, want)
+					return err
+				},
+			})
+		case customHostnamesEqual(have, want):
+			tasks = append(tasks, reconcileCustomHostnamesTask{hostname: want.Hostname, action: ReconcileActionNoop})
+		default:
+			have := have
+			tasks = append(tasks, reconcileCustomHostnamesTask{
+				hostname: want.Hostname,
+				action:   ReconcileActionUpdate,
+				run: func() error {
+					// Send the full desired state, not just SSL: customHostnamesEqual
+					// also considers CustomOriginServer and CustomMetadata drift, and
+					// a PATCH that only carries ssl would report "update" without ever
+					// converging those fields.
+					_, err := api.UpdateCustomHostnameContext(ctx, zoneID, have.ID, want)
+					return err
+				},
+			})
+		}
+	}
+	for _, have := range current {
+		if _, wanted := desiredHostnames[have.Hostname]; wanted {
+			continue
+		}
+		have := have
+		tasks = append(tasks, reconcileCustomHostnamesTask{
+			hostname: have.Hostname,
+			action:   ReconcileActionDelete,
+			run: func() error {
+				return api.DeleteCustomHostnameContext(ctx, zoneID, have.ID)
+			},
+		})
+	}
+
+	report := ReconcileReport{Results: make([]ReconcileResult, len(tasks))}
+	if opts.DryRun {
+		for i, t := range tasks {
+			report.Results[i] = ReconcileResult{Hostname: t.hostname, Action: t.action}
+		}
+		return report, nil
+	}
+
+	limiter := newCloudflareAPIRateLimiter()
+	defer limiter.close()
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range tasks {
+		i, t := i, t
+		if t.run == nil {
+			report.Results[i] = ReconcileResult{Hostname: t.hostname, Action: t.action}
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var callErr error
+			for attempt := 0; attempt <= maxRetries; attempt++ {
+				if err := limiter.wait(ctx); err != nil {
+					callErr = err
+					break
+				}
+				callErr = t.run()
+				if callErr == nil || !isRetryableReconcileError(callErr) {
+					break
+				}
+				select {
+				case <-time.After(reconcileBackoff(attempt)):
+				case <-ctx.Done():
+					callErr = ctx.Err()
+				}
+				if ctx.Err() != nil {
+					break
+				}
+			}
+			report.Results[i] = ReconcileResult{Hostname: t.hostname, Action: t.action, Error: callErr}
+		}()
+	}
+	wg.Wait()
+
+	return report, nil
+}